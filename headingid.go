@@ -7,11 +7,45 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"sync"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
 	"github.com/yuin/goldmark/util"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Strategy selects the slugification algorithm used by Generate.
+type Strategy int
+
+const (
+	// StrategyGitHub mirrors GitHub's heading slugger: the result is
+	// lower-cased and hyphen-separated, but Unicode letters and digits
+	// (anything unicode.IsLetter/IsDigit reports true for) are kept
+	// verbatim instead of being transliterated or dropped.
+	StrategyGitHub Strategy = iota
+
+	// StrategyBlackfriday mirrors the Blackfriday/Hugo default slugger:
+	// case is preserved, runs of whitespace collapse to a single
+	// separator, and only characters outside [\p{L}\p{N}_. -] are
+	// stripped.
+	StrategyBlackfriday
+
+	// StrategyASCII transliterates accented letters (Latin, Greek,
+	// Cyrillic, ...) to their bare base letter by stripping combining
+	// marks, with repl fixing up the handful of letters that don't
+	// decompose that way. What remains non-ASCII is dropped. With
+	// WithASCIIOnly(false), neither step runs: letters and digits in any
+	// script pass through verbatim, only lower-cased. This is the
+	// historical behavior of this package and remains the default for
+	// NewIDs.
+	StrategyASCII
 )
 
 // An IDs interface is a collection of the element ids.
@@ -23,31 +57,77 @@ type IDs interface {
 	Put(value []byte)
 }
 
+// SanitizeFunc, when set via WithSanitizeFunc, replaces the built-in
+// slugify call inside Generate entirely. raw is the heading's raw text
+// and kind is the node it's being generated for; the returned bytes
+// still go through the usual prefix and uniqueness-suffix handling.
+type SanitizeFunc func(raw []byte, kind ast.NodeKind) []byte
+
 type ids struct {
-	values map[string]bool
+	values          map[string]bool
+	strategy        Strategy
+	asciiOnly       bool
+	separator       byte
+	prefix          []byte
+	sanitizeFunc    SanitizeFunc
+	headingFallback string
+	idFallback      string
 }
 
+// NewIDs returns a new IDs using StrategyASCII, preserving the behavior
+// this package has always had.
 func NewIDs() IDs {
-	return &ids{
-		values: map[string]bool{},
+	return NewIDsWithStrategy(StrategyASCII)
+}
+
+// NewIDsWithStrategy returns a new IDs that slugifies heading values
+// according to the given Strategy, customized by the given IDsOptions.
+func NewIDsWithStrategy(strategy Strategy, opts ...IDsOption) IDs {
+	s := &ids{
+		values:          map[string]bool{},
+		strategy:        strategy,
+		asciiOnly:       true,
+		separator:       '-',
+		headingFallback: "heading",
+		idFallback:      "id",
 	}
+	for _, opt := range opts {
+		opt.SetIDsOption(s)
+	}
+	return s
 }
 
 func (s *ids) Generate(value []byte, kind ast.NodeKind) []byte {
-	result := slugify(value,'-')
+	var result []byte
+	switch {
+	case s.sanitizeFunc != nil:
+		result = s.sanitizeFunc(value, kind)
+	case s.strategy == StrategyGitHub:
+		result = slugifyGitHub(value, s.separator)
+	case s.strategy == StrategyBlackfriday:
+		result = slugifyBlackfriday(value, s.separator)
+	default:
+		result = slugifyASCII(value, s.separator, s.asciiOnly)
+	}
 	if len(result) == 0 {
 		if kind == ast.KindHeading {
-			result = []byte("heading")
+			result = []byte(s.headingFallback)
 		} else {
-			result = []byte("id")
+			result = []byte(s.idFallback)
 		}
 	}
+	if len(s.prefix) > 0 {
+		result = append(append(make([]byte, 0, len(s.prefix)+len(result)), s.prefix...), result...)
+	}
 	if _, ok := s.values[util.BytesToReadOnlyString(result)]; !ok {
 		s.values[util.BytesToReadOnlyString(result)] = true
 		return result
 	}
 	for i := 1; ; i++ {
-		newResult := fmt.Sprintf("%s-%d", result, i)
+		// %s on a []byte writes its raw bytes, so this matches the
+		// WriteByte(sep) calls in the slugify functions even when sep
+		// isn't ASCII.
+		newResult := fmt.Sprintf("%s%s%d", result, []byte{s.separator}, i)
 		if _, ok := s.values[newResult]; !ok {
 			s.values[newResult] = true
 			return []byte(newResult)
@@ -59,32 +139,64 @@ func (s *ids) Put(value []byte) {
 	s.values[util.BytesToReadOnlyString(value)] = true
 }
 
-// Map of common unicode runes to ASCII replacements.
+// repl maps the handful of letters that NFD decomposition can't turn
+// into a base Latin letter plus a combining mark (so stripMarks below
+// never sees them) to their ASCII equivalent.
 var repl = map[rune]string{
-	'Á': "A", 'À': "A", 'Â': "A", 'Ä': "A", 'Ã': "A", 'Å': "A", 'á': "a", 'à': "a", 'â': "a", 'ä': "a", 'ã': "a", 'å': "a",
-	'É': "E", 'È': "E", 'Ê': "E", 'Ë': "E", 'é': "e", 'è': "e", 'ê': "e", 'ë': "e",
-	'Í': "I", 'Ì': "I", 'Î': "I", 'Ï': "I", 'í': "i", 'ì': "i", 'î': "i", 'ï': "i",
-	'Ó': "O", 'Ò': "O", 'Ô': "O", 'Ö': "O", 'Õ': "O", 'ó': "o", 'ò': "o", 'ô': "o", 'ö': "o", 'õ': "o",
-	'Ú': "U", 'Ù': "U", 'Û': "U", 'Ü': "U", 'ú': "u", 'ù': "u", 'û': "u", 'ü': "u",
-	'Ñ': "N", 'ñ': "n", 'Ç': "C", 'ç': "c",
-	'Ý': "Y", 'ý': "y", 'ÿ': "y",
-	'Þ': "th", 'þ': "th", 'Ð': "d", 'ð': "d",
-	'Æ': "ae", 'æ': "ae", 'Œ': "oe", 'œ': "oe",
-}
-
-// Slugify converts input bytes to a slug bytes slice using sep (e.g., '-').
-// Result is lowercased ASCII; non-transliterable runes are removed or become sep.
-func slugify(in []byte, sep byte) []byte {
+	'ß': "ss",
+	'æ': "ae", 'Æ': "ae",
+	'œ': "oe", 'Œ': "oe",
+	'þ': "th", 'Þ': "th",
+	'ð': "d", 'Ð': "d",
+	'ø': "o", 'Ø': "o",
+	'đ': "d", 'Đ': "d",
+	'ł': "l", 'Ł': "l",
+}
+
+var (
+	markStripperOnce sync.Once
+	markStripper     transform.Transformer
+)
+
+// stripMarks returns a transformer that NFD-decomposes its input (so
+// e.g. "é" becomes "e" + a combining acute accent), drops nonspacing
+// marks (unicode.Mn, i.e. the accent), and NFC-recomposes what's left.
+// It's built once and reused, since constructing a transform.Chain isn't
+// free and slugifyASCII may be called once per heading in a document.
+func stripMarks() transform.Transformer {
+	markStripperOnce.Do(func() {
+		markStripper = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	})
+	return markStripper
+}
+
+// slugifyASCII converts input bytes to a slug bytes slice using sep (e.g., '-').
+// When asciiOnly is true ("transliterate or drop"), it NFD-normalizes
+// the input and strips combining marks so accented Latin, Greek and
+// Cyrillic letters fall out as their bare base letter, uses repl to fix
+// up the few letters NFD can't decompose, and drops whatever non-ASCII
+// letters/digits remain. When asciiOnly is false, none of that
+// normalization runs: every letter/digit in any script passes through
+// verbatim (marks included), only lower-cased.
+func slugifyASCII(in []byte, sep byte, asciiOnly bool) []byte {
 	if len(in) == 0 {
 		return nil
 	}
+
+	src := in
+	if asciiOnly {
+		if normalized, _, err := transform.Bytes(stripMarks(), in); err == nil {
+			src = normalized
+		}
+	}
+
 	var out bytes.Buffer
-	out.Grow(len(in))
+	out.Grow(len(src))
 	prevSep := false
 
-	for len(in) > 0 {
-		r, size := utf8.DecodeRune(in)
-		in = in[size:]
+	for len(src) > 0 {
+		r, size := utf8.DecodeRune(src)
+		src = src[size:]
 
 		// ASCII fast-path
 		if r < utf8.RuneSelf {
@@ -104,18 +216,31 @@ func slugify(in []byte, sep byte) []byte {
 			continue
 		}
 
-		// Transliterate common runes
+		if !asciiOnly {
+			// Passthrough: every letter/digit (and any combining mark
+			// riding on one) survives verbatim, lower-cased; nothing
+			// is transliterated or dropped.
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+				out.WriteRune(unicode.ToLower(r))
+				prevSep = false
+			} else if !prevSep && out.Len() > 0 {
+				out.WriteByte(sep)
+				prevSep = true
+			}
+			continue
+		}
+
+		// Fix up the few letters NFD can't decompose into base+mark.
 		if s, ok := repl[r]; ok {
-			// write transliteration as lowercase
 			out.WriteString(strings.ToLower(s))
 			prevSep = false
 			continue
 		}
 
-		// For letters in other scripts try to use unicode.IsLetter -> drop if not ASCII
+		// Whatever's left is a letter/digit in a script NFD didn't
+		// reduce to ASCII (Cyrillic, Greek, CJK, ...) -> drop it,
+		// leaving a separator in its place.
 		if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			// Attempt a naive decomposition: remove diacritics isn't cheap; drop unknown non-ASCII.
-			// To keep short and fast, we omit expensive normalization and treat as separator.
 			if !prevSep && out.Len() > 0 {
 				out.WriteByte(sep)
 				prevSep = true
@@ -130,17 +255,319 @@ func slugify(in []byte, sep byte) []byte {
 		}
 	}
 
-	// Trim trailing separator
-	b := out.Bytes()
+	return trimSep(out.Bytes(), sep)
+}
+
+// slugifyGitHub mirrors GitHub's heading slugger: lower-cased and
+// sep-separated, but any Unicode letter or digit is preserved verbatim
+// instead of being transliterated or dropped.
+func slugifyGitHub(in []byte, sep byte) []byte {
+	if len(in) == 0 {
+		return nil
+	}
+	var out bytes.Buffer
+	out.Grow(len(in))
+	prevSep := false
+
+	for len(in) > 0 {
+		r, size := utf8.DecodeRune(in)
+		in = in[size:]
+
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			out.WriteRune(unicode.ToLower(r))
+			prevSep = false
+		default:
+			if !prevSep && out.Len() > 0 {
+				out.WriteByte(sep)
+				prevSep = true
+			}
+		}
+	}
+
+	return trimSep(out.Bytes(), sep)
+}
+
+// slugifyBlackfriday mirrors the Blackfriday/Hugo default slugger: case
+// is preserved, runs of whitespace collapse to sep, and only characters
+// outside [\p{L}\p{N}_. -] are stripped.
+func slugifyBlackfriday(in []byte, sep byte) []byte {
+	if len(in) == 0 {
+		return nil
+	}
+	var out bytes.Buffer
+	out.Grow(len(in))
+	prevSep := false
+
+	for len(in) > 0 {
+		r, size := utf8.DecodeRune(in)
+		in = in[size:]
+
+		switch {
+		case unicode.IsSpace(r):
+			if !prevSep && out.Len() > 0 {
+				out.WriteByte(sep)
+				prevSep = true
+			}
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '.' || r == '-':
+			out.WriteRune(r)
+			prevSep = false
+		default:
+			// stripped entirely, not replaced with sep
+		}
+	}
+
+	return trimSep(out.Bytes(), sep)
+}
+
+// trimSep trims a leading and/or trailing sep byte from b and returns a
+// copy, so callers can safely hold onto the result.
+func trimSep(b []byte, sep byte) []byte {
 	if len(b) > 0 && b[len(b)-1] == sep {
 		b = b[:len(b)-1]
 	}
-	// Trim leading separator
 	if len(b) > 0 && b[0] == sep {
 		b = b[1:]
 	}
-	// Return a copy to ensure external mutation won't affect internal buffer
 	res := make([]byte, len(b))
 	copy(res, b)
 	return res
 }
+
+// headingIDExtender is a goldmark.Extender that assigns generated ids to
+// headings during parsing.
+type headingIDExtender struct {
+	strategy        Strategy
+	asciiOnly       bool
+	separator       byte
+	prefix          []byte
+	sanitizeFunc    SanitizeFunc
+	headingFallback string
+	idFallback      string
+}
+
+// New returns a goldmark.Extender that can be passed to
+// goldmark.WithExtensions to register automatic heading id generation,
+// configured via the given HeadingIDOption values.
+func New(opts ...HeadingIDOption) goldmark.Extender {
+	e := &headingIDExtender{
+		strategy:        StrategyASCII,
+		asciiOnly:       true,
+		separator:       '-',
+		headingFallback: "heading",
+		idFallback:      "id",
+	}
+	for _, opt := range opts {
+		opt.SetHeadingIDOption(e)
+	}
+	return e
+}
+
+func (e *headingIDExtender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithASTTransformers(
+		util.Prioritized(&headingIDTransformer{
+			ids: NewIDsWithStrategy(e.strategy,
+				WithASCIIOnly(e.asciiOnly),
+				WithSeparator(e.separator),
+				WithPrefix(e.prefix),
+				WithSanitizeFunc(e.sanitizeFunc),
+				WithHeadingFallback(e.headingFallback),
+				WithIDFallback(e.idFallback),
+			),
+		}, 100),
+	))
+}
+
+// headingIDTransformer walks the parsed document and assigns a generated
+// id attribute to every heading that doesn't already have one.
+type headingIDTransformer struct {
+	ids IDs
+}
+
+func (t *headingIDTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if v, ok := h.AttributeString("id"); ok {
+			if id, ok := v.([]byte); ok {
+				t.ids.Put(id)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		// h.Text walks every descendant (ast.Text, ast.String,
+		// ast.AutoLink, ...), not just plain text runs, so a heading
+		// like "# <https://example.com>" still gets a real slug
+		// instead of falling back to headingFallback.
+		h.SetAttributeString("id", t.ids.Generate(h.Text(source), h.Kind()))
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+// HeadingIDOption configures a headingIDExtender created via New.
+type HeadingIDOption interface {
+	SetHeadingIDOption(*headingIDExtender)
+}
+
+type withStrategyOption struct {
+	value Strategy
+}
+
+func (o *withStrategyOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.strategy = o.value
+}
+
+// WithStrategy sets the slugification Strategy used by the extension.
+func WithStrategy(strategy Strategy) HeadingIDOption {
+	return &withStrategyOption{value: strategy}
+}
+
+type withASCIIOnlyOption struct {
+	value bool
+}
+
+func (o *withASCIIOnlyOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.asciiOnly = o.value
+}
+
+func (o *withASCIIOnlyOption) SetIDsOption(s *ids) {
+	s.asciiOnly = o.value
+}
+
+// WithASCIIOnly controls how StrategyASCII treats letters/digits that
+// NFD normalization can't reduce to ASCII (Cyrillic, Greek, CJK, ...).
+// When true (the default), they're dropped, matching this package's
+// historical behavior. When false, no normalization runs at all, so
+// they (and accented letters too) pass through to the slug verbatim,
+// only lower-cased, matching what non-Hugo Markdown renderers do for
+// CJK/Cyrillic headings. It has no effect on StrategyGitHub or
+// StrategyBlackfriday, which never drop letters/digits.
+//
+// WithASCIIOnly satisfies both HeadingIDOption and IDsOption, so it can
+// be passed to New as well as to NewIDsWithStrategy.
+func WithASCIIOnly(asciiOnly bool) interface {
+	HeadingIDOption
+	IDsOption
+} {
+	return &withASCIIOnlyOption{value: asciiOnly}
+}
+
+// IDsOption configures an ids instance created via NewIDsWithStrategy.
+type IDsOption interface {
+	SetIDsOption(*ids)
+}
+
+type withSeparatorOption struct {
+	value byte
+}
+
+func (o *withSeparatorOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.separator = o.value
+}
+
+func (o *withSeparatorOption) SetIDsOption(s *ids) {
+	s.separator = o.value
+}
+
+// WithSeparator sets the byte used to join words within a slug and to
+// join the uniqueness counter onto a duplicate id (default '-').
+func WithSeparator(separator byte) interface {
+	HeadingIDOption
+	IDsOption
+} {
+	return &withSeparatorOption{value: separator}
+}
+
+type withPrefixOption struct {
+	value []byte
+}
+
+func (o *withPrefixOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.prefix = o.value
+}
+
+func (o *withPrefixOption) SetIDsOption(s *ids) {
+	s.prefix = o.value
+}
+
+// WithPrefix prepends prefix to every id Generate produces, useful when
+// embedding rendered fragments into a larger page where plain heading
+// slugs might collide with other ids already on it.
+func WithPrefix(prefix []byte) interface {
+	HeadingIDOption
+	IDsOption
+} {
+	return &withPrefixOption{value: prefix}
+}
+
+type withSanitizeFuncOption struct {
+	value SanitizeFunc
+}
+
+func (o *withSanitizeFuncOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.sanitizeFunc = o.value
+}
+
+func (o *withSanitizeFuncOption) SetIDsOption(s *ids) {
+	s.sanitizeFunc = o.value
+}
+
+// WithSanitizeFunc replaces the built-in slugify call inside Generate
+// with fn, while still running its result through the configured
+// prefix and uniqueness-suffix handling. This lets downstream users
+// plug in their own slug policy (e.g. transliteration via
+// github.com/mozillazg/go-unidecode, or preserving CJK) without forking
+// this package.
+func WithSanitizeFunc(fn SanitizeFunc) interface {
+	HeadingIDOption
+	IDsOption
+} {
+	return &withSanitizeFuncOption{value: fn}
+}
+
+type withHeadingFallbackOption struct {
+	value string
+}
+
+func (o *withHeadingFallbackOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.headingFallback = o.value
+}
+
+func (o *withHeadingFallbackOption) SetIDsOption(s *ids) {
+	s.headingFallback = o.value
+}
+
+// WithHeadingFallback sets the id used for an ast.Heading whose slug
+// comes out empty (default "heading").
+func WithHeadingFallback(fallback string) interface {
+	HeadingIDOption
+	IDsOption
+} {
+	return &withHeadingFallbackOption{value: fallback}
+}
+
+type withIDFallbackOption struct {
+	value string
+}
+
+func (o *withIDFallbackOption) SetHeadingIDOption(e *headingIDExtender) {
+	e.idFallback = o.value
+}
+
+func (o *withIDFallbackOption) SetIDsOption(s *ids) {
+	s.idFallback = o.value
+}
+
+// WithIDFallback sets the id used for a non-heading node whose slug
+// comes out empty (default "id").
+func WithIDFallback(fallback string) interface {
+	HeadingIDOption
+	IDsOption
+} {
+	return &withIDFallbackOption{value: fallback}
+}
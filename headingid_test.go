@@ -0,0 +1,208 @@
+package headingid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+)
+
+// fixture is a shared input used across all three strategies so their
+// differences are easy to compare.
+type fixture struct {
+	name string
+	in   string
+}
+
+var strategyFixtures = []fixture{
+	{"plain", "Hello World"},
+	{"extra whitespace", "  Hello   World  "},
+	{"accented latin", "Héllo Wörld"},
+	{"cyrillic", "Привет мир"},
+	{"digits", "123 Go"},
+	{"punctuation", "Hello_World.Test"},
+}
+
+func TestSlugifyStrategies(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy Strategy
+		want     map[string]string
+	}{
+		{
+			name:     "StrategyGitHub",
+			strategy: StrategyGitHub,
+			want: map[string]string{
+				"plain":            "hello-world",
+				"extra whitespace": "hello-world",
+				"accented latin":   "héllo-wörld",
+				"cyrillic":         "привет-мир",
+				"digits":           "123-go",
+				"punctuation":      "hello-world-test",
+			},
+		},
+		{
+			name:     "StrategyBlackfriday",
+			strategy: StrategyBlackfriday,
+			want: map[string]string{
+				"plain":            "Hello-World",
+				"extra whitespace": "Hello-World",
+				"accented latin":   "Héllo-Wörld",
+				"cyrillic":         "Привет-мир",
+				"digits":           "123-Go",
+				"punctuation":      "Hello_World.Test",
+			},
+		},
+		{
+			name:     "StrategyASCII",
+			strategy: StrategyASCII,
+			want: map[string]string{
+				"plain":            "hello-world",
+				"extra whitespace": "hello-world",
+				"accented latin":   "hello-world",
+				"cyrillic":         "heading", // fully dropped -> empty slug -> fallback
+				"digits":           "123-go",
+				"punctuation":      "hello-world-test",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		for _, f := range strategyFixtures {
+			t.Run(c.name+"/"+f.name, func(t *testing.T) {
+				ids := NewIDsWithStrategy(c.strategy)
+				got := string(ids.Generate([]byte(f.in), ast.KindHeading))
+				if want := c.want[f.name]; got != want {
+					t.Errorf("Generate(%q) = %q, want %q", f.in, got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateDuplicatesGetCounterSuffix(t *testing.T) {
+	ids := NewIDsWithStrategy(StrategyGitHub)
+	first := string(ids.Generate([]byte("Hello World"), ast.KindHeading))
+	second := string(ids.Generate([]byte("Hello World"), ast.KindHeading))
+	if first != "hello-world" {
+		t.Fatalf("first = %q, want hello-world", first)
+	}
+	if second != "hello-world-1" {
+		t.Fatalf("second = %q, want hello-world-1", second)
+	}
+}
+
+func TestGenerateEmptyFallsBackByKind(t *testing.T) {
+	ids := NewIDsWithStrategy(StrategyASCII)
+	if got := string(ids.Generate(nil, ast.KindHeading)); got != "heading" {
+		t.Errorf("heading fallback = %q, want %q", got, "heading")
+	}
+	if got := string(ids.Generate(nil, ast.KindDocument)); got != "id" {
+		t.Errorf("non-heading fallback = %q, want %q", got, "id")
+	}
+}
+
+func TestNewWithStrategyOption(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(New(WithStrategy(StrategyGitHub))))
+	var buf bytes.Buffer
+	if err := md.Convert([]byte("# Héllo Wörld\n"), &buf); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	const want = `id="héllo-wörld"`
+	if !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Fatalf("rendered output %q does not contain %s", buf.String(), want)
+	}
+}
+
+// A heading whose only inline content is an autolink has no ast.Text
+// child, so Generate must still see its text instead of falling back
+// to the "heading" placeholder.
+func TestExtenderAutoLinkOnlyHeading(t *testing.T) {
+	md := goldmark.New(goldmark.WithExtensions(New()))
+	var buf bytes.Buffer
+	if err := md.Convert([]byte("# <https://example.com>\n"), &buf); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	const want = `id="https-example-com"`
+	if !bytes.Contains(buf.Bytes(), []byte(want)) {
+		t.Fatalf("rendered output %q does not contain %s", buf.String(), want)
+	}
+}
+
+// TestSlugifyASCIINormalization exercises letters that exist precisely
+// to stress the NFD pipeline: Ł/ł don't decompose at all (repl handles
+// them), ș and ő decompose into a base letter plus a combining mark
+// that stripMarks must remove, and Ǝ is a distinct letter NFD can't
+// reduce to ASCII at all. With asciiOnly, all of that normalization and
+// dropping applies; with passthrough (asciiOnly=false), none of it
+// does — every letter must survive verbatim, only lower-cased,
+// including ones (й, Ё) whose NFD decomposition is a base letter plus a
+// combining mark that a naively-always-applied stripMarks would wrongly
+// strip.
+func TestSlugifyASCIINormalization(t *testing.T) {
+	cases := []struct {
+		in          string
+		asciiOnly   string
+		passThrough string
+	}{
+		{"Łódź", "lodz", "łódź"},
+		{"Brașov", "brasov", "brașov"},
+		{"Győr", "gyor", "győr"},
+		{"ƎƎ", "", "ǝǝ"},
+		{"Ёлка й", "", "ёлка-й"},
+	}
+	for _, c := range cases {
+		if got := string(slugifyASCII([]byte(c.in), '-', true)); got != c.asciiOnly {
+			t.Errorf("slugifyASCII(%q, asciiOnly=true) = %q, want %q", c.in, got, c.asciiOnly)
+		}
+		if got := string(slugifyASCII([]byte(c.in), '-', false)); got != c.passThrough {
+			t.Errorf("slugifyASCII(%q, asciiOnly=false) = %q, want %q", c.in, got, c.passThrough)
+		}
+	}
+}
+
+// TestSlugifyASCIIPrecomposedVsDecomposed checks that a precomposed
+// letter (e.g. "é", U+00E9) and its NFD-decomposed equivalent ("e" +
+// U+0301 COMBINING ACUTE ACCENT) slugify identically, which is the
+// whole point of running input through norm.NFD before stripping marks.
+func TestSlugifyASCIIPrecomposedVsDecomposed(t *testing.T) {
+	precomposed := "café" // caf + U+00E9 LATIN SMALL LETTER E WITH ACUTE
+	decomposed := "café" // cafe + U+0301 COMBINING ACUTE ACCENT
+	if precomposed == decomposed {
+		t.Fatal("test fixture bug: inputs must differ byte-for-byte")
+	}
+	want := "cafe"
+	if got := string(slugifyASCII([]byte(precomposed), '-', true)); got != want {
+		t.Errorf("slugifyASCII(precomposed) = %q, want %q", got, want)
+	}
+	if got := string(slugifyASCII([]byte(decomposed), '-', true)); got != want {
+		t.Errorf("slugifyASCII(decomposed) = %q, want %q", got, want)
+	}
+}
+
+// mixedScriptCorpus is shared by the NFD benchmarks below: a heading-length
+// line of Latin, Cyrillic and Greek text, the kind of input where
+// StrategyASCII pays for normalization but StrategyGitHub doesn't.
+const mixedScriptCorpus = "The Quick Brown Fox: Быстрая лиса и Γρήγορη αλεπού jumps over the lazy dog café naïve"
+
+// BenchmarkSlugifyASCII measures the NFD-normalizing path introduced to
+// replace the old fixed transliteration table.
+func BenchmarkSlugifyASCII(b *testing.B) {
+	in := []byte(mixedScriptCorpus)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		slugifyASCII(in, '-', true)
+	}
+}
+
+// BenchmarkSlugifyGitHub is the non-normalizing baseline over the same
+// corpus, quantifying the overhead the NFD pipeline adds: it never NFD
+// decomposes, so it has nothing to justify.
+func BenchmarkSlugifyGitHub(b *testing.B) {
+	in := []byte(mixedScriptCorpus)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		slugifyGitHub(in, '-')
+	}
+}